@@ -0,0 +1,166 @@
+package errorx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var _ MultiError = (*multiError)(nil)
+
+type (
+	// Collector accumulates the errors in loops, and produces an aggregated CodeError.
+	// The zero value is ready to use, and it's not safe for concurrent use.
+	// For example:
+	//
+	//	c := errorx.NewCollector()
+	//	for _, item := range items {
+	//	    c.Add(process(item))
+	//	}
+	//	return c.Err()
+	Collector struct {
+		code    *ErrCode
+		total   int
+		errs    []error
+		indices []int
+	}
+
+	// CollectorOption is the optional setting for NewCollector.
+	CollectorOption func(*Collector)
+
+	// MultiError is the cause of the aggregated error produced by Collector.Err.
+	MultiError interface {
+		error
+		Errors() []error
+	}
+
+	multiError struct {
+		errs    []error
+		indices []int
+	}
+)
+
+// WithCollectorCode sets the code of the aggregated error, the default is CCBadRequest.
+func WithCollectorCode(c *ErrCode) CollectorOption {
+	return func(col *Collector) {
+		col.code = c
+	}
+}
+
+func NewCollector(opts ...CollectorOption) *Collector {
+	col := &Collector{}
+	for _, opt := range opts {
+		opt(col)
+	}
+	return col
+}
+
+// Add records the result of the next item, the nil err is counted as success.
+func (col *Collector) Add(err error) {
+	if err != nil {
+		col.errs = append(col.errs, err)
+		col.indices = append(col.indices, col.total)
+	}
+	col.total++
+}
+
+// Addf is Add with err annotated by the format message.
+func (col *Collector) Addf(err error, format string, args ...interface{}) {
+	col.Add(Annotate(err, format, args...))
+}
+
+func (col *Collector) HasErrors() bool {
+	return len(col.errs) > 0
+}
+
+// Errors returns a copy of the collected errors.
+func (col *Collector) Errors() []error {
+	return copyErrors(col.errs)
+}
+
+// Err returns the aggregated CodeError whose details list each failure with its index, or nil if there are no errors.
+// The collected errors are kept as the cause, they can be got by AsMultiError and matched by errors.Is and errors.As.
+func (col *Collector) Err() error {
+	if !col.HasErrors() {
+		return nil
+	}
+
+	c := col.code
+	if c == nil {
+		c = newUnregisteredErrCode(CCBadRequest, PlatformDefault, 0, "ErrBadRequest")
+	}
+
+	strs := make([]string, len(col.errs))
+	for i, err := range col.errs {
+		strs[i] = fmt.Sprintf("[%d] %s", col.indices[i], err.Error())
+	}
+	cause := &multiError{
+		errs:    copyErrors(col.errs),
+		indices: append([]int(nil), col.indices...),
+	}
+	return newCodeError(c, cause, 0, "%d of %d failed: %s", len(col.errs), col.total, strings.Join(strs, "; "))
+}
+
+func AsMultiError(err error) (MultiError, bool) {
+	if e := new(multiError); errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+func (e *multiError) Error() string {
+	return fmt.Sprintf("%d errors", len(e.errs))
+}
+
+// Errors returns a copy of the collected errors.
+func (e *multiError) Errors() []error {
+	return copyErrors(e.errs)
+}
+
+// Is reports whether any of the collected errors matches target.
+func (e *multiError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the collected errors that matches target.
+func (e *multiError) As(target interface{}) bool {
+	for _, err := range e.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Format prints each of the collected errors with its index, and %+v prints their stacks.
+func (e *multiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, e.Error())
+			for i, err := range e.errs {
+				_, _ = fmt.Fprintf(s, "\n[%d] %+v", e.indices[i], err)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+func copyErrors(errs []error) []error {
+	if errs == nil {
+		return nil
+	}
+	return append(make([]error, 0, len(errs)), errs...)
+}
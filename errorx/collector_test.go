@@ -0,0 +1,71 @@
+package errorx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	var zero Collector
+	zero.Add(nil)
+	assert.False(t, zero.HasErrors())
+	assert.NoError(t, zero.Err())
+
+	otherErr := errors.New("otherError")
+	col := NewCollector()
+	for i := 0; i < 5; i++ {
+		switch i {
+		case 1:
+			col.Add(WithCode(testErrNotFound, nil))
+		case 3:
+			col.Addf(otherErr, "item %d", i)
+		default:
+			col.Addf(nil, "item %d", i)
+		}
+	}
+
+	assert.True(t, col.HasErrors())
+	assert.Len(t, col.Errors(), 2)
+	err := col.Err()
+	e, ok := AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CCBadRequest, e.GetCategoryCode())
+		assert.Equal(t, "2 of 5 failed: [1] 40401000(testErrNotFound); [3] item 3: otherError", e.GetDetails())
+		assert.Contains(t, fmt.Sprintf("%+v", err), "errorx.TestCollector")
+		assert.NotContains(t, fmt.Sprintf("%+v", err), "errorx.(*Collector).Err")
+		assert.Contains(t, fmt.Sprintf("%+v", err), "\n[3] otherError\ngithub.com/vesoft-inc/go-pkg/errorx.TestCollector")
+		assert.Equal(t, 1, strings.Count(fmt.Sprintf("%+v", err), "item 3: otherError"))
+	}
+	assert.EqualError(t, errors.Unwrap(err), "2 errors")
+
+	assert.True(t, errors.Is(err, otherErr))
+	assert.True(t, errors.Is(err, Sentinel(testErrNotFound)))
+	var target *codeError
+	if assert.True(t, errors.As(errors.Unwrap(err), &target)) {
+		assert.True(t, target.IsErrCode(testErrNotFound))
+	}
+	me, ok := AsMultiError(err)
+	if assert.True(t, ok) {
+		assert.Len(t, me.Errors(), 2)
+		assert.True(t, IsCodeError(me.Errors()[0], testErrNotFound))
+	}
+
+	errs := col.Errors()
+	errs[0] = nil
+	assert.Error(t, col.Errors()[0])
+	_, ok = AsMultiError(otherErr)
+	assert.False(t, ok)
+
+	SetDefaultPlatform(45)
+	defer SetDefaultPlatform(0)
+	assert.Equal(t, 40045000, col.Err().(CodeError).GetCode())
+
+	col = NewCollector(WithCollectorCode(testErrInternalServer))
+	col.Add(errors.New("otherError"))
+	assert.True(t, IsCodeError(col.Err(), testErrInternalServer))
+	assert.Equal(t, "50001000(testErrInternalServer) 1 of 1 failed: [0] otherError", col.Err().Error())
+}
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	_ "unsafe" // for go:linkname
 
 	"github.com/pkg/errors"
@@ -21,10 +26,42 @@ const ( // CodeCategory
 	CCUnknown        = 900                            // 900
 )
 
+// SpecificCode is the specific code of ErrCode, it's an alias of int so that NewErrCode accepts both.
+type SpecificCode = int
+
+// The common specific codes, they are shared across categories and platforms.
+// The specific codes [0, 100) are reserved for the common ones, please define your own specific codes from 100.
+const (
+	SpecGeneral          SpecificCode = 0 // The general error of the category.
+	SpecValidationFailed SpecificCode = 1 // The parameters failed the validation.
+	SpecNotFound         SpecificCode = 2 // The resource is not found.
+	SpecConflict         SpecificCode = 3 // The resource conflicts with the current state, such as already exists.
+	SpecTimeout          SpecificCode = 4 // The operation timed out.
+	SpecUnavailable      SpecificCode = 5 // The dependency is unavailable.
+)
+
+// PlatformDefault is the platform code placeholder for NewErrCode and New, which is replaced by the one set by SetDefaultPlatform.
+const PlatformDefault = -1
+
+// MatchAny is the wildcard of MatchSpec which matches any code.
+const MatchAny = -1
+
+// CategoryNonCode is the key of GroupByCategory for the errors which are not CodeError.
+const CategoryNonCode = -1
+
 var (
-	_              CodeError = (*codeError)(nil)
-	codeCombinerMu sync.Mutex
-	codeCombiner   CodeCombiner = codeCombiner323{}
+	_               CodeError = (*codeError)(nil)
+	codeCombinerMu  sync.Mutex
+	codeCombiner    CodeCombiner = codeCombiner323{}
+	reasonRegexp                 = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+	registryMu      sync.RWMutex
+	registry        = map[int][]*ErrCode{}
+	platformMu      sync.RWMutex
+	platformNames         = map[int]string{}
+	maxStackDepth   int32 = 32
+	defaultPlatform int32
+	reporterMu      sync.RWMutex
+	reporter        func(error)
 )
 
 type (
@@ -43,8 +80,15 @@ type (
 	ErrCode struct {
 		code    int
 		message string
+		reason  string
 	}
 
+	// ErrCodeOption is the optional setting for NewErrCode.
+	ErrCodeOption func(*ErrCode)
+
+	// StackSkip is the number of extra caller frames to skip when capturing the stack, see WithStackSkip.
+	StackSkip int
+
 	CodeError interface {
 		error
 		GetErrCode() *ErrCode
@@ -62,7 +106,8 @@ type (
 		error
 		*ErrCode
 		*stack
-		details string
+		details  string
+		sentinel bool
 	}
 
 	CodeCombiner interface {
@@ -70,9 +115,29 @@ type (
 		Separate(int) (categoryCode, platformCode, specificCode int)
 	}
 
+	// CodeLayoutDescriber is optionally implemented by CodeCombiner to describe its layout, see DescribeCodeLayout.
+	CodeLayoutDescriber interface {
+		DescribeLayout() CodeLayout
+	}
+
+	// CodeLayout describes how the codes are combined into the code.
+	CodeLayout struct {
+		CategoryDigits int    `json:"categoryDigits"`
+		PlatformDigits int    `json:"platformDigits"`
+		SpecificDigits int    `json:"specificDigits"`
+		Description    string `json:"description"`
+	}
+
 	codeCombiner323 struct{}
 
 	stack []uintptr
+
+	// MatchSpec is the pattern for Matches, each code can be MatchAny.
+	MatchSpec struct {
+		CategoryCode int
+		PlatformCode int
+		SpecificCode int
+	}
 )
 
 // SetCodeCombiner changes the default CodeCombiner.
@@ -82,6 +147,106 @@ func SetCodeCombiner(combiner CodeCombiner) {
 	codeCombinerMu.Unlock()
 }
 
+// RegisterPlatformName registers a human-readable name for the platform code.
+func RegisterPlatformName(platformCode int, name string) {
+	platformMu.Lock()
+	platformNames[platformCode] = name
+	platformMu.Unlock()
+}
+
+// SetDefaultPlatform sets the platform code of the service, which is used for PlatformDefault and WrapWithPlatform.
+// It must be called before the *ErrCode with PlatformDefault are created. The global *ErrCode are created
+// during the package initialization, even before the init functions of the same package, so call it in the init
+// function of a separate package, which is imported by the package declaring the codes.
+// For example:
+//
+//	package platform
+//
+//	func init() { errorx.SetDefaultPlatform(45) }
+//
+//	package ecode
+//
+//	import _ "example.com/service/platform"
+//
+//	var ErrUserNotFound = errorx.NewErrCode(errorx.CCNotFound, errorx.PlatformDefault, 1, "ErrUserNotFound")
+func SetDefaultPlatform(platformCode int) {
+	atomic.StoreInt32(&defaultPlatform, int32(platformCode))
+}
+
+// WrapWithPlatform rewrites the platform code of the outermost CodeError in err to the default platform if it's 0,
+// the cause, details and stack are preserved. It returns err directly otherwise.
+// The registered *ErrCode with the rewritten code is used if it's unique, so that it can be matched by IsCodeError.
+func WrapWithPlatform(err error) error {
+	ce := new(codeError)
+	if !errors.As(err, &ce) {
+		return err
+	}
+	categoryCode, platformCode, specificCode := codeCombiner.Separate(ce.GetCode())
+	if platformCode != 0 || getDefaultPlatform() == 0 {
+		return err
+	}
+
+	c := lookupErrCode(codeCombiner.Combine(categoryCode, getDefaultPlatform(), specificCode))
+	if c == nil {
+		c = newUnregisteredErrCode(categoryCode, PlatformDefault, specificCode, ce.message)
+		c.reason = ce.reason
+	}
+	nce := *ce
+	nce.ErrCode = c
+	nce.sentinel = false
+	if ce == err {
+		return &nce
+	}
+	if ce.details != "" {
+		return WithCode(c, err, "%s", ce.details)
+	}
+	return WithCode(c, err)
+}
+
+// SetErrorReporter changes the default reporter used by Report.
+func SetErrorReporter(fn func(error)) {
+	reporterMu.Lock()
+	reporter = fn
+	reporterMu.Unlock()
+}
+
+// Tee passes err to fn and returns err unchanged, fn is not called if err is nil.
+// For example:
+//
+//	return errorx.Tee(reportMetrics, errorx.WithCode(ErrInternalServer, err))
+func Tee(fn func(error), err error) error {
+	if err != nil && fn != nil {
+		fn(err)
+	}
+	return err
+}
+
+// Report is Tee with the default reporter set by SetErrorReporter.
+func Report(err error) error {
+	reporterMu.RLock()
+	fn := reporter
+	reporterMu.RUnlock()
+	return Tee(fn, err)
+}
+
+// SetMaxStackDepth changes the max number of frames captured for CodeError, the default is 32.
+// Zero or negative disables the stack capture.
+func SetMaxStackDepth(n int) {
+	if n < 0 {
+		n = 0
+	}
+	atomic.StoreInt32(&maxStackDepth, int32(n))
+}
+
+// DescribeCodeLayout returns the layout of the current CodeCombiner,
+// it returns false if the CodeCombiner doesn't implement CodeLayoutDescriber.
+func DescribeCodeLayout() (CodeLayout, bool) {
+	if d, ok := codeCombiner.(CodeLayoutDescriber); ok {
+		return d.DescribeLayout(), true
+	}
+	return CodeLayout{}, false
+}
+
 // WithCode return error warps with codeError.
 // c is the code. err is the real err. formatWithArgs is details with format string including args.
 // For example:
@@ -90,14 +255,67 @@ func SetCodeCombiner(combiner CodeCombiner) {
 //	WithCode(ErrBadRequest, err)
 //	WithCode(ErrBadRequest, err, "details")
 //	WithCode(ErrBadRequest, err, "details %s", "id")
+//
+// The first of formatWithArgs can be WithStackSkip to skip the frames of thin wrappers:
+//
+//	WithCode(ErrBadRequest, err, WithStackSkip(1), "details %s", "id")
 func WithCode(c *ErrCode, err error, formatWithArgs ...interface{}) error {
+	skip := 0
+	if len(formatWithArgs) > 0 {
+		if v, ok := formatWithArgs[0].(StackSkip); ok {
+			skip = int(v)
+			formatWithArgs = formatWithArgs[1:]
+		}
+	}
+	return newCodeError(c, err, skip, formatWithArgs...)
+}
+
+// New returns a coded error with an inline *ErrCode, it's for the rare codes without a global *ErrCode.
+// The *ErrCode is not listed by All.
+func New(categoryCode, platformCode int, specificCode SpecificCode, message string, skip ...StackSkip) error {
+	n := 0
+	if len(skip) > 0 {
+		n = int(skip[0])
+	}
+	return newCodeError(newUnregisteredErrCode(categoryCode, platformCode, specificCode, message), nil, n)
+}
+
+// WithStackSkip makes WithCode and New skip n extra caller frames when capturing the stack,
+// so that the stack starts at the true caller when they are called through thin wrappers.
+func WithStackSkip(n int) StackSkip {
+	return StackSkip(n)
+}
+
+// Unimplemented returns a CCNotImplemented coded error for the feature, it's a placeholder during development.
+// The platform code is the default platform set by SetDefaultPlatform.
+func Unimplemented(feature string) error {
+	return newCodeError(newUnregisteredErrCode(CCNotImplemented, PlatformDefault, 0, "ErrNotImplemented"), nil, 0,
+		"%s is not implemented", feature)
+}
+
+// TODO returns a CCNotImplemented coded error for the calling function, it's a placeholder during development.
+func TODO() error {
+	feature := "unknown"
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) > 0 {
+		if frame, _ := runtime.CallersFrames(pcs[:]).Next(); frame.Function != "" {
+			feature = frame.Function
+		}
+	}
+	return newCodeError(newUnregisteredErrCode(CCNotImplemented, PlatformDefault, 0, "ErrNotImplemented"), nil, 0,
+		"TODO: %s is not implemented", feature)
+}
+
+// newCodeError creates the codeError, the stack starts at the caller of the exported function which calls it,
+// and the skip is the number of extra frames to skip.
+func newCodeError(c *ErrCode, err error, skip int, formatWithArgs ...interface{}) *codeError {
 	ce := &codeError{
 		error:   err,
 		ErrCode: c,
 	}
 
 	if !hasStack(err) {
-		ce.stack = callers()
+		ce.stack = callers(skip)
 	}
 
 	if len(formatWithArgs) > 0 {
@@ -109,6 +327,78 @@ func WithCode(c *ErrCode, err error, formatWithArgs ...interface{}) error {
 	return ce
 }
 
+// WithCause returns a copy of the outermost CodeError in codeErr with the cause set or replaced by cause,
+// the code and details are preserved. The wrappers outside the CodeError are dropped.
+// It returns codeErr directly if it's not a CodeError.
+func WithCause(codeErr, cause error) error {
+	ce := new(codeError)
+	if !errors.As(codeErr, &ce) {
+		return codeErr
+	}
+
+	nce := *ce
+	nce.error = cause
+	nce.sentinel = false
+	if hasStack(cause) {
+		nce.stack = nil
+	} else if nce.stack == nil {
+		nce.stack = callers(-1) // WithCause is called directly instead of via newCodeError
+	}
+	return &nce
+}
+
+// Matches reports whether err is a CodeError whose codes match spec.
+// For example:
+//
+//	Matches(err, MatchSpec{CategoryCode: CCNotFound, PlatformCode: MatchAny, SpecificCode: MatchAny})
+func Matches(err error, spec MatchSpec) bool {
+	e, ok := AsCodeError(err)
+	if !ok {
+		return false
+	}
+	categoryCode, platformCode, specificCode := codeCombiner.Separate(e.GetCode())
+	return matchCode(spec.CategoryCode, categoryCode) &&
+		matchCode(spec.PlatformCode, platformCode) &&
+		matchCode(spec.SpecificCode, specificCode)
+}
+
+// IsCategory reports whether err is a CodeError with the category code.
+func IsCategory(err error, categoryCode int) bool {
+	return Matches(err, MatchSpec{CategoryCode: categoryCode, PlatformCode: MatchAny, SpecificCode: MatchAny})
+}
+
+// IsPlatform reports whether err is a CodeError with the platform code.
+func IsPlatform(err error, platformCode int) bool {
+	return Matches(err, MatchSpec{CategoryCode: MatchAny, PlatformCode: platformCode, SpecificCode: MatchAny})
+}
+
+// IsSpecific reports whether err is a CodeError with the specific code.
+func IsSpecific(err error, specificCode int) bool {
+	return Matches(err, MatchSpec{CategoryCode: MatchAny, PlatformCode: MatchAny, SpecificCode: specificCode})
+}
+
+// Sentinel returns a reusable error value with the code c, it can be matched by both IsCodeError and errors.Is.
+// The errors.Is also matches any CodeError with the same *ErrCode in the chain.
+// For example:
+//
+//	var ErrNotFoundSentinel = errorx.Sentinel(ErrNotFound)
+//
+//	return ErrNotFoundSentinel
+//	errors.Is(err, ErrNotFoundSentinel)
+func Sentinel(c *ErrCode) error {
+	return &codeError{
+		ErrCode:  c,
+		sentinel: true,
+	}
+}
+
+// Annotate adds the formatted message to err while keeping its code.
+// Unlike WithCode, it does not wrap err in a new codeError, so AsCodeError still finds the original code.
+// It returns nil if err is nil.
+func Annotate(err error, format string, args ...interface{}) error {
+	return errors.WithMessagef(err, format, args...)
+}
+
 func AsCodeError(err error) (CodeError, bool) {
 	if e := new(codeError); errors.As(err, &e) {
 		return e, true
@@ -131,17 +421,273 @@ func IsCodeError(err error, c ...*ErrCode) bool {
 	}
 }
 
+// GroupByCategory buckets errs by the category code, the errors which are not CodeError are under CategoryNonCode.
+// The nil errors are ignored.
+func GroupByCategory(errs []error) map[int][]error {
+	groups := map[int][]error{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		categoryCode := CategoryNonCode
+		if e, ok := AsCodeError(err); ok {
+			categoryCode = e.GetCategoryCode()
+		}
+		groups[categoryCode] = append(groups[categoryCode], err)
+	}
+	return groups
+}
+
+// WalkChain calls fn for each error in the chain of err from the outermost one.
+// The code is the *ErrCode of the link if it's a CodeError, otherwise nil.
+// The walking stops when fn returns false.
+func WalkChain(err error, fn func(err error, code *ErrCode) bool) {
+	for err != nil {
+		var code *ErrCode
+		if e, ok := err.(*codeError); ok {
+			code = e.GetErrCode()
+		}
+		if !fn(err, code) {
+			return
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// FieldsOf returns the flat fields of err for structured logging.
+// The fields of a CodeError are code, categoryCode, platformCode, specificCode, message, reason and details,
+// the reason and details are omitted if empty.
+// The fields of other errors only contain error.
+func FieldsOf(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := AsCodeError(err)
+	if !ok {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	categoryCode, platformCode, specificCode := codeCombiner.Separate(e.GetCode())
+	fields := map[string]interface{}{
+		"code":         e.GetCode(),
+		"categoryCode": categoryCode,
+		"platformCode": platformCode,
+		"specificCode": specificCode,
+		"message":      e.GetMessage(),
+	}
+	if reason := e.GetErrCode().GetReason(); reason != "" {
+		fields["reason"] = reason
+	}
+	if details := e.GetDetails(); details != "" {
+		fields["details"] = details
+	}
+	return fields
+}
+
+// Explain returns a human-readable multiline explanation of err, including the code, category, platform,
+// http status, message, reason, details and the cause chain.
+func Explain(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	if e, ok := AsCodeError(err); ok {
+		categoryCode, platformCode, specificCode := codeCombiner.Separate(e.GetCode())
+		_, _ = fmt.Fprintf(&sb, "Code:     %d\n", e.GetCode())
+		_, _ = fmt.Fprintf(&sb, "Category: %d (%s)\n", categoryCode, getCategoryName(categoryCode))
+		_, _ = fmt.Fprintf(&sb, "Platform: %d (%s)\n", platformCode, e.GetErrCode().GetPlatformName())
+		_, _ = fmt.Fprintf(&sb, "Specific: %d\n", specificCode)
+		_, _ = fmt.Fprintf(&sb, "HTTP:     %d %s\n", e.GetHTTPStatus(), e.GetErrCode().GetHTTPStatusText())
+		_, _ = fmt.Fprintf(&sb, "Message:  %s\n", e.GetMessage())
+		if reason := e.GetErrCode().GetReason(); reason != "" {
+			_, _ = fmt.Fprintf(&sb, "Reason:   %s\n", reason)
+		}
+		if details := e.GetDetails(); details != "" {
+			_, _ = fmt.Fprintf(&sb, "Details:  %s\n", details)
+		}
+	} else {
+		_, _ = fmt.Fprintf(&sb, "Error:    %s\n", err.Error())
+	}
+
+	sb.WriteString("Chain:\n")
+	WalkChain(err, func(err error, _ *ErrCode) bool {
+		_, _ = fmt.Fprintf(&sb, "  - %T: %s\n", err, err.Error())
+		return true
+	})
+	return sb.String()
+}
+
+// UserString returns the user-facing string of err, which only contains the code and message, such as "40401000(ErrNotFound)".
+// It returns the text of http.StatusInternalServerError if err is not a CodeError, so that internal details are not exposed.
+func UserString(err error) string {
+	if err == nil {
+		return ""
+	}
+	if e, ok := AsCodeError(err); ok {
+		return formatErrCode(e.GetErrCode())
+	}
+	return http.StatusText(http.StatusInternalServerError)
+}
+
+// DebugString returns the developer-facing string of err, which contains the details, causes and stack.
+func DebugString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+v", err)
+}
+
+// EqualCode reports whether a and b are CodeError with the same combined code, regardless of the messages and wrapping.
+// If neither of them is CodeError, it reports whether they are the same error,
+// the errors of an uncomparable type, such as a slice, are never the same.
+func EqualCode(a, b error) bool {
+	ae, aOk := AsCodeError(a)
+	be, bOk := AsCodeError(b)
+	if aOk && bOk {
+		return ae.GetCode() == be.GetCode()
+	}
+	if !aOk && !bOk {
+		if t := reflect.TypeOf(a); t != nil && t == reflect.TypeOf(b) && !t.Comparable() {
+			return false
+		}
+		return a == b
+	}
+	return false
+}
+
+// IsClientError reports whether err is a CodeError whose http status is 4xx.
+func IsClientError(err error) bool {
+	e, ok := AsCodeError(err)
+	if !ok {
+		return false
+	}
+	httpStatus := e.GetHTTPStatus()
+	return httpStatus >= http.StatusBadRequest && httpStatus < http.StatusInternalServerError
+}
+
+// IsServerError reports whether err is a CodeError whose http status is 5xx, the CCUnknown is treated as 5xx.
+// The non-nil errors which are not CodeError are also server errors.
+func IsServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+	e, ok := AsCodeError(err)
+	if !ok {
+		return true
+	}
+	return e.GetHTTPStatus() >= http.StatusInternalServerError
+}
+
 // SeparateCode splits code with category code, platform code and specific code.
 func SeparateCode(code int) (categoryCode, platformCode, specificCode int) {
 	return codeCombiner.Separate(code)
 }
 
 // NewErrCode is create an new *ErrCode, it's only used for global initialization.
-func NewErrCode(categoryCode, platformCode, specificCode int, message string) *ErrCode {
-	return &ErrCode{
-		code:    codeCombiner.Combine(categoryCode, platformCode, specificCode),
+// The *ErrCode is registered and listed by All. Registering several *ErrCode with the same combined code is a mistake,
+// All lists all of them so that the collisions can be detected, such as in a unit test.
+func NewErrCode(categoryCode, platformCode int, specificCode SpecificCode, message string, opts ...ErrCodeOption) *ErrCode {
+	c := NewUnregisteredErrCode(categoryCode, platformCode, specificCode, message, opts...)
+	register(c)
+	return c
+}
+
+// NewUnregisteredErrCode is NewErrCode without the registration, so the *ErrCode is not listed by All.
+// It's for the codes created by libraries, which should not be added to the registry of the service.
+func NewUnregisteredErrCode(categoryCode, platformCode int, specificCode SpecificCode, message string, opts ...ErrCodeOption) *ErrCode {
+	c := newUnregisteredErrCode(categoryCode, platformCode, specificCode, message)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// All returns a snapshot of all registered *ErrCode sorted by the combined code.
+// When several *ErrCode share the same combined code, all of them are returned in the order of creation.
+func All() []*ErrCode {
+	registryMu.RLock()
+	codes := make([]*ErrCode, 0, len(registry))
+	for _, cs := range registry {
+		codes = append(codes, cs...)
+	}
+	registryMu.RUnlock()
+
+	sort.SliceStable(codes, func(i, j int) bool {
+		return codes[i].GetCode() < codes[j].GetCode()
+	})
+	return codes
+}
+
+// RestoreErrCode restores an *ErrCode from the combined code, such as the one decoded from a remote response.
+// The *ErrCode is not listed by All.
+func RestoreErrCode(code int, message string, opts ...ErrCodeOption) *ErrCode {
+	c := &ErrCode{
+		code:    code,
 		message: message,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// IsValidReason reports whether reason is a valid uppercase-snake reason for WithReason.
+func IsValidReason(reason string) bool {
+	return reasonRegexp.MatchString(reason)
+}
+
+// Must returns c if err is nil, otherwise it panics.
+// It simplifies the global initialization of *ErrCode from a loader that can error.
+// For example:
+//
+//	var ErrNotFound = errorx.Must(loadErrCode("ErrNotFound"))
+func Must(c *ErrCode, err error) *ErrCode {
+	if err != nil {
+		panic(fmt.Sprintf("errorx: Must(%s): %s", formatErrCode(c), err))
+	}
+	return c
+}
+
+// MustLoad is like Must but for loaders that return multiple *ErrCode.
+func MustLoad(codes []*ErrCode, err error) []*ErrCode {
+	if err != nil {
+		strs := make([]string, len(codes))
+		for i, c := range codes {
+			strs[i] = formatErrCode(c)
+		}
+		panic(fmt.Sprintf("errorx: MustLoad(%v): %s", strs, err))
+	}
+	return codes
+}
+
+// WithReason sets a stable machine-readable reason slug, such as "RESOURCE_NOT_FOUND".
+// The reason must be uppercase-snake, otherwise it panics.
+func WithReason(reason string) ErrCodeOption {
+	if !IsValidReason(reason) {
+		panic(fmt.Sprintf("errorx: invalid reason %q, must be uppercase-snake", reason))
+	}
+	return func(c *ErrCode) {
+		c.reason = reason
+	}
+}
+
+// WithCategory returns a new *ErrCode with the category code replaced by newCategory,
+// the platform code, specific code, message and reason are preserved.
+// The newCategory must be CCUnknown or a known 4xx or 5xx http status code, otherwise it panics.
+func WithCategory(c *ErrCode, newCategory int) *ErrCode {
+	if !isErrorCategory(newCategory) {
+		panic(fmt.Sprintf("errorx: invalid category code %d", newCategory))
+	}
+	_, platformCode, specificCode := codeCombiner.Separate(c.GetCode())
+	return &ErrCode{
+		code:    codeCombiner.Combine(newCategory, platformCode, specificCode),
+		message: c.message,
+		reason:  c.reason,
+	}
 }
 
 func TakeCodePriority(fns ...func() *ErrCode) *ErrCode {
@@ -171,6 +717,19 @@ func (c *ErrCode) GetPlatformCode() int {
 	return v
 }
 
+// GetPlatformName returns the name registered by RegisterPlatformName,
+// or "Platform(NN)" if the platform code is not registered.
+func (c *ErrCode) GetPlatformName() string {
+	platformCode := c.GetPlatformCode()
+	platformMu.RLock()
+	name, ok := platformNames[platformCode]
+	platformMu.RUnlock()
+	if ok {
+		return name
+	}
+	return fmt.Sprintf("Platform(%02d)", platformCode)
+}
+
 func (c *ErrCode) GetSpecificCode() int {
 	_, _, v := codeCombiner.Separate(c.GetCode())
 	return v
@@ -180,10 +739,19 @@ func (c *ErrCode) GetMessage() string {
 	return c.message
 }
 
+func (c *ErrCode) GetReason() string {
+	return c.reason
+}
+
 func (c *ErrCode) GetHTTPStatus() int {
 	return getErrCodeHTTPStatus(c)
 }
 
+// GetHTTPStatusText returns the standard http status text of GetHTTPStatus.
+func (c *ErrCode) GetHTTPStatusText() string {
+	return http.StatusText(c.GetHTTPStatus())
+}
+
 func (c *ErrCode) IsErrCode(ec *ErrCode) bool {
 	return c == ec
 }
@@ -199,6 +767,12 @@ func (e *codeError) Error() string {
 	return fmt.Sprintf("%d(%s)", e.GetCode(), e.GetMessage())
 }
 
+// Is reports whether target is a Sentinel with the same *ErrCode.
+func (e *codeError) Is(target error) bool {
+	t, ok := target.(*codeError)
+	return ok && t.sentinel && e.IsErrCode(t.ErrCode)
+}
+
 func (e *codeError) Cause() error { return e.error }
 
 // Unwrap provides compatibility for Go 1.13 error chains.
@@ -233,6 +807,75 @@ func (codeCombiner323) Separate(code int) (categoryCode, platformCode, specificC
 	return code / 100000, code / 1000 % 100, code % 1000
 }
 
+func getCategoryName(categoryCode int) string {
+	if categoryCode == CCUnknown {
+		return "Unknown"
+	}
+	if text := http.StatusText(categoryCode); text != "" {
+		return text
+	}
+	return fmt.Sprintf("Category(%d)", categoryCode)
+}
+
+func matchCode(pattern, code int) bool {
+	return pattern == MatchAny || pattern == code
+}
+
+func formatErrCode(c *ErrCode) string {
+	if c == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%d(%s)", c.GetCode(), c.GetMessage())
+}
+
+// newUnregisteredErrCode creates an *ErrCode for internal use which is not listed by All.
+func newUnregisteredErrCode(categoryCode, platformCode, specificCode int, message string) *ErrCode {
+	if platformCode == PlatformDefault {
+		platformCode = getDefaultPlatform()
+	}
+	return &ErrCode{
+		code:    codeCombiner.Combine(categoryCode, platformCode, specificCode),
+		message: message,
+	}
+}
+
+func getDefaultPlatform() int {
+	return int(atomic.LoadInt32(&defaultPlatform))
+}
+
+// isErrorCategory reports whether categoryCode is CCUnknown or a known 4xx or 5xx http status code.
+func isErrorCategory(categoryCode int) bool {
+	if categoryCode == CCUnknown {
+		return true
+	}
+	return categoryCode >= http.StatusBadRequest && categoryCode < 600 && http.StatusText(categoryCode) != ""
+}
+
+// lookupErrCode returns the registered *ErrCode with the combined code, or nil if there is none or more than one.
+func lookupErrCode(code int) *ErrCode {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if cs := registry[code]; len(cs) == 1 {
+		return cs[0]
+	}
+	return nil
+}
+
+func register(c *ErrCode) {
+	registryMu.Lock()
+	registry[c.code] = append(registry[c.code], c)
+	registryMu.Unlock()
+}
+
+func (codeCombiner323) DescribeLayout() CodeLayout {
+	return CodeLayout{
+		CategoryDigits: 3,
+		PlatformDigits: 2,
+		SpecificDigits: 3,
+		Description:    "code = categoryCode*100000 + platformCode*1000 + specificCode",
+	}
+}
+
 func getErrCodeHTTPStatus(c *ErrCode) int {
 	categoryCode := c.GetCategoryCode()
 	if categoryCode == CCUnknown {
@@ -250,16 +893,22 @@ func hasStack(err error) bool {
 		if _, ok := err.(stackTracer); ok {
 			return true
 		}
+		if ce, ok := err.(*codeError); ok && ce.stack != nil {
+			return true
+		}
 		err = errors.Unwrap(err)
 	}
 
 	return false
 }
 
-func callers() *stack {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(3, pcs[:])
+func callers(skip int) *stack {
+	depth := atomic.LoadInt32(&maxStackDepth)
+	if depth <= 0 {
+		return nil
+	}
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(4+skip, pcs)
 	var st stack = pcs[0:n]
 	return &st
 }
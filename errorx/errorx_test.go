@@ -3,6 +3,7 @@ package errorx
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"testing"
 
@@ -73,6 +74,204 @@ func Test_NewErrCode(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, c.GetHTTPStatus())
 }
 
+func Test_GetHTTPStatusText(t *testing.T) {
+	assert.Equal(t, "Not Found", testErrNotFound.GetHTTPStatusText())
+	assert.Equal(t, "Internal Server Error", testErrUnknown.GetHTTPStatusText())
+	e, ok := AsCodeError(WithCode(testErrForbidden, nil))
+	if assert.True(t, ok) {
+		assert.Equal(t, "Forbidden", e.GetErrCode().GetHTTPStatusText())
+	}
+}
+
+func Test_SpecificCode(t *testing.T) {
+	var specificCode SpecificCode = 100
+	c := NewErrCode(CCBadRequest, 45, SpecValidationFailed, "msg")
+	assert.Equal(t, 40045001, c.GetCode())
+	assert.Equal(t, SpecValidationFailed, c.GetSpecificCode())
+	assert.Equal(t, 40445002, NewErrCode(CCNotFound, 45, SpecNotFound, "msg").GetCode())
+	assert.Equal(t, 40045100, NewErrCode(CCBadRequest, 45, specificCode, "msg").GetCode())
+	assert.True(t, IsSpecific(WithCode(NewErrCode(CCInternalServer, 45, SpecTimeout, "msg"), nil), SpecTimeout))
+}
+
+func Test_WithReason(t *testing.T) {
+	c := NewErrCode(CCNotFound, 45, 67, "msg", WithReason("RESOURCE_NOT_FOUND"))
+	assert.Equal(t, 40445067, c.GetCode())
+	assert.Equal(t, "RESOURCE_NOT_FOUND", c.GetReason())
+
+	e, ok := AsCodeError(WithCode(c, nil))
+	assert.True(t, ok)
+	assert.Equal(t, "RESOURCE_NOT_FOUND", e.GetErrCode().GetReason())
+
+	assert.Equal(t, "", NewErrCode(CCNotFound, 45, 67, "msg").GetReason())
+
+	for _, reason := range []string{"", "resource_not_found", "RESOURCE__NOT", "_RESOURCE", "RESOURCE_", "RESOURCE-NOT"} {
+		assert.Panics(t, func() { WithReason(reason) }, reason)
+	}
+}
+
+func Test_All(t *testing.T) {
+	codes := All()
+	assert.True(t, sort.SliceIsSorted(codes, func(i, j int) bool {
+		return codes[i].GetCode() < codes[j].GetCode()
+	}))
+	for _, c := range []*ErrCode{testErrBadRequest, testErrParam, testErrNotFound, testErrUnknown} {
+		assert.Contains(t, codes, c)
+	}
+	assert.Less(t, indexOfErrCode(codes, testErrBadRequest), indexOfErrCode(codes, testErrParam))
+	assert.Less(t, indexOfErrCode(codes, testErrParam), indexOfErrCode(codes, testErrNotFound))
+	assert.Less(t, indexOfErrCode(codes, testErrNotFound), indexOfErrCode(codes, testErrUnknown))
+
+	codes[0] = nil
+	assert.NotContains(t, All(), (*ErrCode)(nil))
+
+	c := NewUnregisteredErrCode(CCNotFound, 46, 1, "msg", WithReason("NOT_FOUND"))
+	assert.Equal(t, 40446001, c.GetCode())
+	assert.Equal(t, "NOT_FOUND", c.GetReason())
+	assert.Equal(t, -1, indexOfErrCode(All(), c))
+
+	first := NewErrCode(CCNotFound, 46, 2, "first")
+	second := NewErrCode(CCNotFound, 46, 2, "second")
+	codes = All()
+	assert.Equal(t, indexOfErrCode(codes, first)+1, indexOfErrCode(codes, second))
+	assert.Nil(t, lookupErrCode(40446002))
+}
+
+func Test_Must(t *testing.T) {
+	assert.Equal(t, testErrNotFound, Must(testErrNotFound, nil))
+	assert.PanicsWithValue(t, "errorx: Must(40401000(testErrNotFound)): load failed", func() {
+		Must(testErrNotFound, errors.New("load failed"))
+	})
+	assert.PanicsWithValue(t, "errorx: Must(<nil>): load failed", func() {
+		Must(nil, errors.New("load failed"))
+	})
+
+	codes := []*ErrCode{testErrBadRequest, testErrNotFound}
+	assert.Equal(t, codes, MustLoad(codes, nil))
+	assert.PanicsWithValue(t, "errorx: MustLoad([40001000(testErrBadRequest) 40401000(testErrNotFound)]): load failed", func() {
+		MustLoad(codes, errors.New("load failed"))
+	})
+}
+
+func Test_WithCategory(t *testing.T) {
+	c := WithCategory(testErrParam, CCInternalServer)
+	assert.Equal(t, 50001001, c.GetCode())
+	assert.Equal(t, CCInternalServer, c.GetCategoryCode())
+	assert.Equal(t, testCPCloudServer, c.GetPlatformCode())
+	assert.Equal(t, 1, c.GetSpecificCode())
+	assert.Equal(t, testErrParam.GetMessage(), c.GetMessage())
+	assert.False(t, c.IsErrCode(testErrParam))
+	assert.Equal(t, 40001001, testErrParam.GetCode())
+
+	assert.Equal(t, 90001001, WithCategory(testErrParam, CCUnknown).GetCode())
+	assert.Equal(t, 50301001, WithCategory(testErrParam, http.StatusServiceUnavailable).GetCode())
+	assert.Panics(t, func() { WithCategory(testErrParam, 0) })
+	assert.Panics(t, func() { WithCategory(testErrParam, 999) })
+	assert.Panics(t, func() { WithCategory(testErrParam, http.StatusOK) })
+	assert.Panics(t, func() { WithCategory(testErrParam, http.StatusFound) })
+}
+
+func Test_GetPlatformName(t *testing.T) {
+	defer func() {
+		platformMu.Lock()
+		delete(platformNames, testCPCloudServer)
+		platformMu.Unlock()
+	}()
+
+	assert.Equal(t, "Platform(01)", testErrNotFound.GetPlatformName())
+
+	RegisterPlatformName(testCPCloudServer, "cloud-server")
+	e, ok := AsCodeError(WithCode(testErrNotFound, nil))
+	assert.True(t, ok)
+	assert.Equal(t, "cloud-server", e.GetErrCode().GetPlatformName())
+	assert.Equal(t, "Platform(45)", NewErrCode(CCNotFound, 45, 0, "msg").GetPlatformName())
+}
+
+func Test_SetDefaultPlatform(t *testing.T) {
+	defer SetDefaultPlatform(0)
+
+	assert.Equal(t, 40400002, NewErrCode(CCNotFound, PlatformDefault, 2, "msg").GetCode())
+	assert.Equal(t, 40400002, New(CCNotFound, PlatformDefault, 2, "msg").(CodeError).GetCode())
+
+	err := WithCode(NewErrCode(CCNotFound, 0, 2, "msg"), nil)
+	assert.Equal(t, err, WrapWithPlatform(err))
+
+	SetDefaultPlatform(45)
+	c := NewErrCode(CCNotFound, PlatformDefault, 2, "msg")
+	assert.Equal(t, 40445002, c.GetCode())
+	assert.Equal(t, 40445002, New(CCNotFound, PlatformDefault, 2, "msg").(CodeError).GetCode())
+	assert.Equal(t, 40401002, NewErrCode(CCNotFound, testCPCloudServer, 2, "msg").GetCode())
+
+	assert.NoError(t, WrapWithPlatform(nil))
+	otherErr := errors.New("otherError")
+	assert.Equal(t, otherErr, WrapWithPlatform(otherErr))
+	err = WithCode(testErrNotFound, nil)
+	assert.Equal(t, err, WrapWithPlatform(err))
+
+	cause := errors.New("otherError")
+	err = WithCode(NewErrCode(CCNotFound, 0, 3, "msg", WithReason("NOT_FOUND")), cause, "details")
+	for _, wrapped := range []error{
+		WrapWithPlatform(err),
+		WrapWithPlatform(errors.WithMessage(err, "annotation")),
+	} {
+		e, ok := AsCodeError(wrapped)
+		if assert.True(t, ok) {
+			assert.Equal(t, 40445003, e.GetCode())
+			assert.Equal(t, "msg", e.GetMessage())
+			assert.Equal(t, "NOT_FOUND", e.GetErrCode().GetReason())
+			assert.Equal(t, "details", e.GetDetails())
+			assert.True(t, errors.Is(wrapped, cause))
+			assert.Contains(t, fmt.Sprintf("%+v", wrapped), "errorx.Test_SetDefaultPlatform")
+		}
+	}
+
+	registered := NewErrCode(CCNotFound, PlatformDefault, 4, "registered")
+	err = WithCode(NewErrCode(CCNotFound, 0, 4, "msg"), cause, "details")
+	for _, wrapped := range []error{
+		WrapWithPlatform(err),
+		WrapWithPlatform(errors.WithMessage(err, "annotation")),
+	} {
+		assert.True(t, IsCodeError(wrapped, registered))
+		assert.True(t, errors.Is(wrapped, Sentinel(registered)))
+		e, ok := AsCodeError(wrapped)
+		if assert.True(t, ok) {
+			assert.Equal(t, "registered", e.GetMessage())
+			assert.Equal(t, "details", e.GetDetails())
+			assert.True(t, errors.Is(wrapped, cause))
+		}
+	}
+}
+
+func Test_RestoreErrCode(t *testing.T) {
+	c := RestoreErrCode(40445067, "msg", WithReason("RESOURCE_NOT_FOUND"))
+	assert.Equal(t, 40445067, c.GetCode())
+	assert.Equal(t, CCNotFound, c.GetCategoryCode())
+	assert.Equal(t, 45, c.GetPlatformCode())
+	assert.Equal(t, 67, c.GetSpecificCode())
+	assert.Equal(t, "msg", c.GetMessage())
+	assert.Equal(t, "RESOURCE_NOT_FOUND", c.GetReason())
+	assert.Equal(t, -1, indexOfErrCode(All(), c))
+
+	assert.True(t, IsValidReason("RESOURCE_NOT_FOUND"))
+	assert.False(t, IsValidReason("resource_not_found"))
+}
+
+func Test_DescribeCodeLayout(t *testing.T) {
+	layout, ok := DescribeCodeLayout()
+	assert.True(t, ok)
+	assert.Equal(t, CodeLayout{
+		CategoryDigits: 3,
+		PlatformDigits: 2,
+		SpecificDigits: 3,
+		Description:    "code = categoryCode*100000 + platformCode*1000 + specificCode",
+	}, layout)
+
+	curCodeCombiner := codeCombiner
+	defer SetCodeCombiner(curCodeCombiner)
+	SetCodeCombiner(testCodeCombiner{})
+	_, ok = DescribeCodeLayout()
+	assert.False(t, ok)
+}
+
 func Test_SetCodeCombiner(t *testing.T) {
 	curCodeCombiner := codeCombiner
 	defer SetCodeCombiner(curCodeCombiner)
@@ -138,10 +337,150 @@ func TestWithCode(t *testing.T) {
 			assert.Equal(t, fmt.Sprintf("%q", err), fmt.Sprintf("%q", test.expectedErrStr))
 			stackCount := strings.Count(fmt.Sprintf("%+v", err), "errorx.TestWithCode")
 			assert.Equal(t, 1, stackCount)
+			if test.err == nil {
+				assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), test.expectedErrStr+"\ngithub.com/vesoft-inc/go-pkg/errorx.TestWithCode"))
+			}
 		})
 	}
 }
 
+func TestWithCodeStackOnce(t *testing.T) {
+	inner := WithCode(testErrNotFound, nil, "inner")
+	err := WithCode(testErrInternalServer, inner, "outer")
+	err = WithCode(testErrBadRequest, errors.WithMessage(err, "annotation"))
+
+	e, ok := AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, testErrBadRequest.GetCode(), e.GetCode())
+	}
+	assert.True(t, IsCodeError(err, testErrBadRequest))
+
+	str := fmt.Sprintf("%+v", err)
+	assert.Equal(t, 1, strings.Count(str, "errorx.TestWithCodeStackOnce"))
+	assert.Contains(t, str, inner.Error()+"\ngithub.com/vesoft-inc/go-pkg/errorx.TestWithCodeStackOnce\n")
+
+	var stackCount int
+	WalkChain(err, func(err error, code *ErrCode) bool {
+		if ce, ok := err.(*codeError); ok && ce.stack != nil {
+			stackCount++
+		}
+		return true
+	})
+	assert.Equal(t, 1, stackCount)
+}
+
+func TestSetMaxStackDepth(t *testing.T) {
+	defer SetMaxStackDepth(32)
+
+	countFrames := func(err error) int {
+		return strings.Count(fmt.Sprintf("%+v", err), "\n\t")
+	}
+
+	assert.Greater(t, countFrames(WithCode(testErrNotFound, nil)), 2)
+
+	for _, n := range []int{1, 2} {
+		SetMaxStackDepth(n)
+		err := WithCode(testErrNotFound, nil)
+		assert.Equal(t, n, countFrames(err))
+		assert.Contains(t, fmt.Sprintf("%+v", err), "errorx.TestSetMaxStackDepth")
+	}
+
+	for _, n := range []int{0, -1} {
+		SetMaxStackDepth(n)
+		err := WithCode(testErrNotFound, nil)
+		assert.Equal(t, 0, countFrames(err))
+		assert.Equal(t, "40401000(testErrNotFound)", fmt.Sprintf("%+v", err))
+	}
+}
+
+func TestTee(t *testing.T) {
+	var reported []error
+	fn := func(err error) {
+		reported = append(reported, err)
+	}
+
+	assert.NoError(t, Tee(fn, nil))
+	assert.Empty(t, reported)
+
+	err := WithCode(testErrInternalServer, errors.New("otherError"))
+	assert.Equal(t, err, Tee(fn, err))
+	assert.Equal(t, []error{err}, reported)
+	assert.Equal(t, err, Tee(nil, err))
+
+	reported = nil
+	assert.Equal(t, err, Report(err))
+	assert.Empty(t, reported)
+
+	SetErrorReporter(fn)
+	defer SetErrorReporter(nil)
+	assert.NoError(t, Report(nil))
+	assert.Equal(t, err, Report(err))
+	assert.Equal(t, []error{err}, reported)
+}
+
+func TestNew(t *testing.T) {
+	err := New(CCNotFound, 45, 67, "msg")
+	e, ok := AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, 40445067, e.GetCode())
+		assert.Equal(t, CCNotFound, e.GetCategoryCode())
+		assert.Equal(t, 45, e.GetPlatformCode())
+		assert.Equal(t, 67, e.GetSpecificCode())
+		assert.Equal(t, "msg", e.GetMessage())
+		assert.Nil(t, errors.Unwrap(err))
+	}
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), "40445067(msg)\ngithub.com/vesoft-inc/go-pkg/errorx.TestNew\n"))
+	assert.Equal(t, -1, indexOfErrCode(All(), e.GetErrCode()))
+}
+
+func TestWithStackSkip(t *testing.T) {
+	wrapWithCode := func() error {
+		return WithCode(testErrNotFound, nil, WithStackSkip(1), "details %d", 10)
+	}
+	wrapNew := func() error {
+		return New(CCNotFound, 45, 67, "msg", WithStackSkip(1))
+	}
+	wrapTwice := func() error {
+		return func() error {
+			return WithCode(testErrNotFound, nil, WithStackSkip(2))
+		}()
+	}
+
+	err := wrapWithCode()
+	assert.Equal(t, "40401000(testErrNotFound) details 10", err.Error())
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), err.Error()+"\ngithub.com/vesoft-inc/go-pkg/errorx.TestWithStackSkip\n"))
+
+	err = wrapNew()
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), err.Error()+"\ngithub.com/vesoft-inc/go-pkg/errorx.TestWithStackSkip\n"))
+
+	err = wrapTwice()
+	assert.Equal(t, "40401000(testErrNotFound)", err.Error())
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), err.Error()+"\ngithub.com/vesoft-inc/go-pkg/errorx.TestWithStackSkip\n"))
+}
+
+func TestUnimplemented(t *testing.T) {
+	err := Unimplemented("export")
+	e, ok := AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CCNotImplemented, e.GetCategoryCode())
+		assert.Equal(t, "50100000(ErrNotImplemented) export is not implemented", err.Error())
+	}
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), err.Error()+"\ngithub.com/vesoft-inc/go-pkg/errorx.TestUnimplemented\n"))
+
+	err = testTODO()
+	e, ok = AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CCNotImplemented, e.GetCategoryCode())
+		assert.Equal(t, "50100000(ErrNotImplemented) TODO: github.com/vesoft-inc/go-pkg/errorx.testTODO is not implemented", err.Error())
+	}
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), err.Error()+"\ngithub.com/vesoft-inc/go-pkg/errorx.testTODO\n"))
+
+	SetDefaultPlatform(45)
+	defer SetDefaultPlatform(0)
+	assert.Equal(t, 50145000, Unimplemented("export").(CodeError).GetCode())
+	assert.Equal(t, 50145000, TODO().(CodeError).GetCode())
+}
+
 func TestAsCodeError(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -215,6 +554,414 @@ func TestAsCodeError(t *testing.T) {
 	}
 }
 
+func TestMatches(t *testing.T) {
+	err := errors.WithMessage(WithCode(testErrParam, nil), "annotation")
+
+	tests := []struct {
+		name     string
+		err      error
+		spec     MatchSpec
+		expected bool
+	}{{
+		name:     "nil err",
+		err:      nil,
+		spec:     MatchSpec{CategoryCode: MatchAny, PlatformCode: MatchAny, SpecificCode: MatchAny},
+		expected: false,
+	}, {
+		name:     "other error",
+		err:      errors.New("otherError"),
+		spec:     MatchSpec{CategoryCode: MatchAny, PlatformCode: MatchAny, SpecificCode: MatchAny},
+		expected: false,
+	}, {
+		name:     "all any",
+		err:      err,
+		spec:     MatchSpec{CategoryCode: MatchAny, PlatformCode: MatchAny, SpecificCode: MatchAny},
+		expected: true,
+	}, {
+		name:     "full match",
+		err:      err,
+		spec:     MatchSpec{CategoryCode: CCBadRequest, PlatformCode: testCPCloudServer, SpecificCode: 1},
+		expected: true,
+	}, {
+		name:     "category",
+		err:      err,
+		spec:     MatchSpec{CategoryCode: CCBadRequest, PlatformCode: MatchAny, SpecificCode: MatchAny},
+		expected: true,
+	}, {
+		name:     "platform and specific",
+		err:      err,
+		spec:     MatchSpec{CategoryCode: MatchAny, PlatformCode: testCPCloudServer, SpecificCode: 1},
+		expected: true,
+	}, {
+		name:     "category not match",
+		err:      err,
+		spec:     MatchSpec{CategoryCode: CCNotFound, PlatformCode: MatchAny, SpecificCode: MatchAny},
+		expected: false,
+	}, {
+		name:     "specific not match",
+		err:      err,
+		spec:     MatchSpec{CategoryCode: CCBadRequest, PlatformCode: testCPCloudServer, SpecificCode: 0},
+		expected: false,
+	}, {
+		name:     "zero value",
+		err:      err,
+		spec:     MatchSpec{},
+		expected: false,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Matches(test.err, test.spec))
+		})
+	}
+
+	assert.True(t, IsCategory(err, CCBadRequest))
+	assert.False(t, IsCategory(err, CCNotFound))
+	assert.True(t, IsPlatform(err, testCPCloudServer))
+	assert.False(t, IsPlatform(err, 0))
+	assert.True(t, IsSpecific(err, 1))
+	assert.False(t, IsSpecific(err, 0))
+}
+
+func TestEqualCode(t *testing.T) {
+	otherErr := errors.New("otherError")
+	tests := []struct {
+		name     string
+		a, b     error
+		expected bool
+	}{{
+		name:     "nil",
+		a:        nil,
+		b:        nil,
+		expected: true,
+	}, {
+		name:     "same code",
+		a:        WithCode(testErrNotFound, nil),
+		b:        errors.WithMessage(WithCode(testErrNotFound, errors.New("otherError"), "details"), "annotation"),
+		expected: true,
+	}, {
+		name:     "same combined code",
+		a:        WithCode(testErrNotFound, nil),
+		b:        WithCode(NewErrCode(CCNotFound, testCPCloudServer, 0, "otherMessage"), nil),
+		expected: true,
+	}, {
+		name:     "different code",
+		a:        WithCode(testErrNotFound, nil),
+		b:        WithCode(testErrBadRequest, nil),
+		expected: false,
+	}, {
+		name:     "code and non-code",
+		a:        WithCode(testErrNotFound, nil),
+		b:        otherErr,
+		expected: false,
+	}, {
+		name:     "code and nil",
+		a:        nil,
+		b:        WithCode(testErrNotFound, nil),
+		expected: false,
+	}, {
+		name:     "same non-code",
+		a:        otherErr,
+		b:        otherErr,
+		expected: true,
+	}, {
+		name:     "different non-code",
+		a:        otherErr,
+		b:        errors.New("otherError"),
+		expected: false,
+	}, {
+		name:     "uncomparable non-code",
+		a:        testValidationErrors{{field: "Name", tag: "required"}},
+		b:        testValidationErrors{{field: "Name", tag: "required"}},
+		expected: false,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, EqualCode(test.a, test.b))
+			assert.Equal(t, test.expected, EqualCode(test.b, test.a))
+		})
+	}
+}
+
+func TestIsClientServerError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedClient bool
+		expectedServer bool
+	}{{
+		name: "nil",
+		err:  nil,
+	}, {
+		name:           "other error",
+		err:            errors.New("otherError"),
+		expectedServer: true,
+	}, {
+		name:           "400",
+		err:            WithCode(testErrBadRequest, nil),
+		expectedClient: true,
+	}, {
+		name:           "401",
+		err:            WithCode(testErrUnauthorized, nil),
+		expectedClient: true,
+	}, {
+		name:           "403",
+		err:            WithCode(testErrForbidden, nil),
+		expectedClient: true,
+	}, {
+		name:           "404 wrapped",
+		err:            errors.WithMessage(WithCode(testErrNotFound, nil), "annotation"),
+		expectedClient: true,
+	}, {
+		name:           "429",
+		err:            New(http.StatusTooManyRequests, 0, 0, "msg"),
+		expectedClient: true,
+	}, {
+		name:           "500",
+		err:            WithCode(testErrInternalServer, nil),
+		expectedServer: true,
+	}, {
+		name:           "501",
+		err:            WithCode(testErrNotImplemented, nil),
+		expectedServer: true,
+	}, {
+		name:           "503",
+		err:            New(http.StatusServiceUnavailable, 0, 0, "msg"),
+		expectedServer: true,
+	}, {
+		name:           "900",
+		err:            WithCode(testErrUnknown, nil),
+		expectedServer: true,
+	}, {
+		name: "300",
+		err:  New(http.StatusMultipleChoices, 0, 0, "msg"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedClient, IsClientError(test.err))
+			assert.Equal(t, test.expectedServer, IsServerError(test.err))
+		})
+	}
+}
+
+func TestSentinel(t *testing.T) {
+	sentinel := Sentinel(testErrNotFound)
+	otherSentinel := Sentinel(testErrBadRequest)
+
+	assert.Equal(t, "40401000(testErrNotFound)", sentinel.Error())
+	assert.Equal(t, "40401000(testErrNotFound)", fmt.Sprintf("%+v", sentinel))
+
+	for _, err := range []error{
+		sentinel,
+		errors.WithMessage(sentinel, "annotation"),
+		errors.WithStack(sentinel),
+		WithCode(testErrInternalServer, sentinel),
+		WithCode(testErrNotFound, nil),
+		errors.WithMessage(WithCode(testErrNotFound, errors.New("otherError")), "annotation"),
+	} {
+		assert.True(t, errors.Is(err, sentinel), err.Error())
+		assert.False(t, errors.Is(err, otherSentinel), err.Error())
+		assert.True(t, IsCodeError(err))
+	}
+	assert.True(t, IsCodeError(sentinel, testErrNotFound))
+	assert.True(t, IsCodeError(errors.WithMessage(sentinel, "annotation"), testErrNotFound))
+
+	assert.False(t, errors.Is(errors.New("otherError"), sentinel))
+	assert.False(t, errors.Is(WithCode(testErrNotFound, nil), WithCode(testErrNotFound, nil)))
+}
+
+func TestWithCause(t *testing.T) {
+	otherErr := errors.New("otherError")
+	assert.Equal(t, otherErr, WithCause(otherErr, errors.New("cause")))
+	assert.NoError(t, WithCause(nil, errors.New("cause")))
+
+	cause := fmt.Errorf("cause")
+	codeErr := WithCode(testErrNotFound, nil, "details")
+	err := WithCause(codeErr, cause)
+	assert.True(t, IsCodeError(err, testErrNotFound))
+	assert.Equal(t, cause, errors.Unwrap(err))
+	assert.Nil(t, errors.Unwrap(codeErr))
+	assert.Equal(t, "40401000(testErrNotFound) details", err.Error())
+	str := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(str, "40401000(testErrNotFound) details:cause\n"))
+	assert.Equal(t, 1, strings.Count(str, "errorx.TestWithCause"))
+
+	// replace the cause
+	replacedCause := fmt.Errorf("replacedCause")
+	err = WithCause(errors.WithMessage(err, "annotation"), replacedCause)
+	assert.True(t, IsCodeError(err, testErrNotFound))
+	assert.Equal(t, replacedCause, errors.Unwrap(err))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "details:replacedCause")
+
+	// the stack of the cause is used
+	err = WithCause(WithCode(testErrNotFound, nil), errors.New("stackCause"))
+	assert.Equal(t, 1, strings.Count(fmt.Sprintf("%+v", err), "errorx.TestWithCause"))
+
+	// the stack is captured if neither has
+	err = WithCause(WithCode(testErrNotFound, errors.New("stackCause")), cause)
+	expectedPrefix := "40401000(testErrNotFound):cause\ngithub.com/vesoft-inc/go-pkg/errorx.TestWithCause\n"
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), expectedPrefix))
+
+	sentinel := Sentinel(testErrNotFound)
+	err = WithCause(sentinel, cause)
+	assert.True(t, errors.Is(err, sentinel))
+	assert.Equal(t, "40401000(testErrNotFound)", sentinel.Error())
+	assert.Nil(t, errors.Unwrap(sentinel))
+}
+
+func TestAnnotate(t *testing.T) {
+	assert.NoError(t, Annotate(nil, "annotation"))
+
+	err := WithCode(testErrNotFound, errors.New("otherError"))
+	err = Annotate(err, "first %d", 1)
+	err = Annotate(err, "second %d", 2)
+
+	assert.Equal(t, "second 2: first 1: 40401000(testErrNotFound)", err.Error())
+	e, ok := AsCodeError(err)
+	assert.True(t, ok)
+	assert.Equal(t, testErrNotFound.GetCode(), e.GetCode())
+	assert.True(t, IsCodeError(err, testErrNotFound))
+}
+
+func TestGroupByCategory(t *testing.T) {
+	assert.Equal(t, map[int][]error{}, GroupByCategory(nil))
+
+	notFound0 := WithCode(testErrNotFound, nil)
+	notFound1 := errors.WithMessage(WithCode(testErrNotFound, nil), "annotation")
+	badRequest := WithCode(testErrParam, nil)
+	internalServer := WithCode(testErrInternalServer, nil)
+	otherErr := errors.New("otherError")
+
+	assert.Equal(t, map[int][]error{
+		CCNotFound:       {notFound0, notFound1},
+		CCBadRequest:     {badRequest},
+		CCInternalServer: {internalServer},
+		CategoryNonCode:  {otherErr},
+	}, GroupByCategory([]error{notFound0, nil, badRequest, otherErr, notFound1, internalServer, nil}))
+}
+
+func TestWalkChain(t *testing.T) {
+	WalkChain(nil, func(err error, code *ErrCode) bool {
+		assert.Fail(t, "should not be called")
+		return true
+	})
+
+	rootErr := errors.New("otherError")
+	err := WithCode(testErrInternalServer, errors.WithMessage(WithCode(testErrNotFound, rootErr), "annotation"))
+
+	var (
+		errStrs []string
+		codes   []*ErrCode
+	)
+	WalkChain(err, func(err error, code *ErrCode) bool {
+		errStrs = append(errStrs, err.Error())
+		codes = append(codes, code)
+		return true
+	})
+	assert.Equal(t, []string{
+		"50001000(testErrInternalServer)",
+		"annotation: 40401000(testErrNotFound)",
+		"40401000(testErrNotFound)",
+		"otherError",
+	}, errStrs)
+	assert.Equal(t, []*ErrCode{testErrInternalServer, nil, testErrNotFound, nil}, codes)
+
+	count := 0
+	WalkChain(err, func(err error, code *ErrCode) bool {
+		count++
+		return code == nil
+	})
+	assert.Equal(t, 1, count)
+}
+
+func TestFieldsOf(t *testing.T) {
+	assert.Nil(t, FieldsOf(nil))
+	assert.Equal(t, map[string]interface{}{
+		"error": "otherError",
+	}, FieldsOf(errors.New("otherError")))
+
+	c := NewErrCode(CCNotFound, testCPCloudServer, 2, "msg", WithReason("RESOURCE_NOT_FOUND"))
+	err := errors.WithMessage(WithCode(c, errors.New("otherError"), "id %d", 10), "annotation")
+	assert.Equal(t, map[string]interface{}{
+		"code":         40401002,
+		"categoryCode": CCNotFound,
+		"platformCode": testCPCloudServer,
+		"specificCode": 2,
+		"message":      "msg",
+		"reason":       "RESOURCE_NOT_FOUND",
+		"details":      "id 10",
+	}, FieldsOf(err))
+
+	assert.Equal(t, map[string]interface{}{
+		"code":         40001000,
+		"categoryCode": CCBadRequest,
+		"platformCode": testCPCloudServer,
+		"specificCode": 0,
+		"message":      "testErrBadRequest",
+	}, FieldsOf(WithCode(testErrBadRequest, nil)))
+}
+
+func TestExplain(t *testing.T) {
+	defer func() {
+		platformMu.Lock()
+		delete(platformNames, testCPCloudServer)
+		platformMu.Unlock()
+	}()
+	RegisterPlatformName(testCPCloudServer, "cloud-server")
+
+	assert.Equal(t, "", Explain(nil))
+
+	c := NewErrCode(CCNotFound, testCPCloudServer, 2, "msg", WithReason("RESOURCE_NOT_FOUND"))
+	err := errors.WithMessage(WithCode(c, fmt.Errorf("otherError"), "id %d", 10), "annotation")
+	assert.Equal(t, `Code:     40401002
+Category: 404 (Not Found)
+Platform: 1 (cloud-server)
+Specific: 2
+HTTP:     404 Not Found
+Message:  msg
+Reason:   RESOURCE_NOT_FOUND
+Details:  id 10
+Chain:
+  - *errors.withMessage: annotation: 40401002(msg) id 10
+  - *errorx.codeError: 40401002(msg) id 10
+  - *errors.errorString: otherError
+`, Explain(err))
+
+	assert.Equal(t, `Code:     90000000
+Category: 900 (Unknown)
+Platform: 0 (Platform(00))
+Specific: 0
+HTTP:     500 Internal Server Error
+Message:  msg
+Chain:
+  - *errorx.codeError: 90000000(msg)
+`, Explain(WithCode(NewErrCode(CCUnknown, 0, 0, "msg"), nil)))
+
+	assert.Equal(t, `Error:    otherError
+Chain:
+  - *errors.errorString: otherError
+`, Explain(fmt.Errorf("otherError")))
+}
+
+func TestUserDebugString(t *testing.T) {
+	assert.Equal(t, "", UserString(nil))
+	assert.Equal(t, "", DebugString(nil))
+
+	err := errors.WithMessage(WithCode(testErrNotFound, errors.New("otherError"), "myDetails %d", 10), "annotation")
+	assert.Equal(t, "40401000(testErrNotFound)", UserString(err))
+	debugStr := DebugString(err)
+	assert.Equal(t, fmt.Sprintf("%+v", err), debugStr)
+	assert.Contains(t, debugStr, "40401000(testErrNotFound) myDetails 10:otherError")
+	assert.Contains(t, debugStr, "errorx.TestUserDebugString")
+	assert.Contains(t, debugStr, "annotation")
+
+	err = errors.New("otherError")
+	assert.Equal(t, "Internal Server Error", UserString(err))
+	assert.Contains(t, DebugString(err), "otherError")
+}
+
 func Test_IsCodeError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -275,6 +1022,19 @@ func Test_IsCodeError(t *testing.T) {
 	}
 }
 
+func testTODO() error {
+	return TODO()
+}
+
+func indexOfErrCode(codes []*ErrCode, c *ErrCode) int {
+	for i := range codes {
+		if codes[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
 func (testCodeCombiner) Combine(categoryCode, platformCode, specificCode int) int {
 	return categoryCode*100 + platformCode*10 + specificCode
 }
@@ -0,0 +1,121 @@
+package grpcx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/vesoft-inc/go-pkg/errorx"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Domain is the domain of the errdetails.ErrorInfo produced by ToStatusDetails.
+	Domain = "errorx.vesoft-inc.com"
+
+	reasonCode = "ERRORX_CODE"
+
+	metadataCode    = "code"
+	metadataMessage = "message"
+	metadataReason  = "reason"
+	metadataDetails = "details"
+)
+
+var httpStatusGRPCCodeMapping = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// ToStatusDetails converts the CodeError in err into an errdetails.ErrorInfo, which carries the code, message, reason and details.
+// It returns nil if err is not a CodeError.
+func ToStatusDetails(err error) proto.Message {
+	e, ok := errorx.AsCodeError(err)
+	if !ok {
+		return nil
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason: reasonCode,
+		Domain: Domain,
+		Metadata: map[string]string{
+			metadataCode:    strconv.Itoa(e.GetCode()),
+			metadataMessage: e.GetMessage(),
+		},
+	}
+	if reason := e.GetErrCode().GetReason(); reason != "" {
+		info.Reason = reason
+		info.Metadata[metadataReason] = reason
+	}
+	if details := e.GetDetails(); details != "" {
+		info.Metadata[metadataDetails] = details
+	}
+	return info
+}
+
+// ToStatus converts err into a *status.Status, the grpc code is derived from the http status of the CodeError,
+// and the CodeError is carried by the status details.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	details := ToStatusDetails(err)
+	if details == nil {
+		return status.New(codes.Unknown, err.Error())
+	}
+
+	e, _ := errorx.AsCodeError(err)
+	code, ok := httpStatusGRPCCodeMapping[e.GetHTTPStatus()]
+	if !ok {
+		code = codes.Unknown
+	}
+	st, stErr := status.New(code, err.Error()).WithDetails(details.(*errdetails.ErrorInfo))
+	if stErr != nil {
+		return status.New(code, err.Error())
+	}
+	return st
+}
+
+// FromStatus reconstructs the CodeError from the status details produced by ToStatusDetails.
+// It returns false if there is no such details.
+func FromStatus(st *status.Status) (error, bool) { //nolint:revive
+	if st == nil {
+		return nil, false
+	}
+
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+		code, err := strconv.Atoi(info.GetMetadata()[metadataCode])
+		if err != nil {
+			continue
+		}
+
+		var opts []errorx.ErrCodeOption
+		if reason := info.GetMetadata()[metadataReason]; errorx.IsValidReason(reason) {
+			opts = append(opts, errorx.WithReason(reason))
+		}
+		c := errorx.RestoreErrCode(code, info.GetMetadata()[metadataMessage], opts...)
+		if details := info.GetMetadata()[metadataDetails]; details != "" {
+			return errorx.WithCode(c, nil, "%s", details), true
+		}
+		return errorx.WithCode(c, nil), true
+	}
+	return nil, false
+}
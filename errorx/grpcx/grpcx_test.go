@@ -0,0 +1,87 @@
+package grpcx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vesoft-inc/go-pkg/errorx"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testErrNotFound = errorx.NewErrCode(errorx.CCNotFound, 1, 2, "testErrNotFound", errorx.WithReason("RESOURCE_NOT_FOUND"))
+
+func TestStatusRoundTrip(t *testing.T) {
+	err := errorx.WithCode(testErrNotFound, errors.New("otherError"), "id %d", 10)
+
+	st := ToStatus(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "40401002(testErrNotFound) id 10", st.Message())
+
+	// round trip through the wire format
+	st = status.FromProto(st.Proto())
+	restoredErr, ok := FromStatus(st)
+	if assert.True(t, ok) {
+		e, ok := errorx.AsCodeError(restoredErr)
+		if assert.True(t, ok) {
+			assert.Equal(t, 40401002, e.GetCode())
+			assert.Equal(t, "testErrNotFound", e.GetMessage())
+			assert.Equal(t, "RESOURCE_NOT_FOUND", e.GetErrCode().GetReason())
+			assert.Equal(t, "id 10", e.GetDetails())
+		}
+	}
+
+	err = errorx.WithCode(errorx.NewErrCode(errorx.CCUnknown, 1, 3, "testErrUnknown"), nil)
+	st = status.FromProto(ToStatus(err).Proto())
+	assert.Equal(t, codes.Internal, st.Code())
+	restoredErr, ok = FromStatus(st)
+	if assert.True(t, ok) {
+		assert.Equal(t, "90001003(testErrUnknown)", restoredErr.Error())
+		e, _ := errorx.AsCodeError(restoredErr)
+		assert.Equal(t, "", e.GetErrCode().GetReason())
+	}
+}
+
+func TestToStatusDetails(t *testing.T) {
+	assert.Nil(t, ToStatusDetails(nil))
+	assert.Nil(t, ToStatusDetails(errors.New("otherError")))
+
+	info, ok := ToStatusDetails(errorx.WithCode(testErrNotFound, nil)).(*errdetails.ErrorInfo)
+	if assert.True(t, ok) {
+		assert.Equal(t, "RESOURCE_NOT_FOUND", info.GetReason())
+		assert.Equal(t, Domain, info.GetDomain())
+		assert.Equal(t, map[string]string{
+			"code":    "40401002",
+			"message": "testErrNotFound",
+			"reason":  "RESOURCE_NOT_FOUND",
+		}, info.GetMetadata())
+	}
+}
+
+func TestToStatusFromStatus(t *testing.T) {
+	assert.Equal(t, codes.OK, ToStatus(nil).Code())
+
+	st := ToStatus(errors.New("otherError"))
+	assert.Equal(t, codes.Unknown, st.Code())
+	assert.Equal(t, "otherError", st.Message())
+
+	st = status.New(codes.Unavailable, "unavailable")
+	assert.Equal(t, st, ToStatus(st.Err()))
+
+	_, ok := FromStatus(nil)
+	assert.False(t, ok)
+	_, ok = FromStatus(st)
+	assert.False(t, ok)
+
+	st, err := status.New(codes.Internal, "internal").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "OTHER",
+		Domain:   "other.domain",
+		Metadata: map[string]string{"code": "50000000"},
+	})
+	assert.NoError(t, err)
+	_, ok = FromStatus(st)
+	assert.False(t, ok)
+}
@@ -0,0 +1,159 @@
+package errorx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ProblemContentType is the content type of ProblemDetails.
+const ProblemContentType = "application/problem+json"
+
+// maxHTTPClientErrorBodySize is the max number of bytes read from the body by WrapHTTPClientError.
+const maxHTTPClientErrorBodySize = 1 << 20
+
+type (
+	// ProblemDetails is the RFC 7807 problem details object.
+	ProblemDetails struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail,omitempty"`
+		Instance string `json:"instance,omitempty"`
+		Code     int    `json:"code"`
+	}
+
+	httpHeadersError struct {
+		error
+		header http.Header
+	}
+
+	httpErrorEnvelope struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+)
+
+// WithHTTPHeaders attaches the http response headers to err, such as WWW-Authenticate or Retry-After.
+// It returns nil if err is nil.
+func WithHTTPHeaders(err error, header http.Header) error {
+	if err == nil {
+		return nil
+	}
+	return &httpHeadersError{error: err, header: header}
+}
+
+// GetHTTPHeaders returns a copy of the http response headers attached by WithHTTPHeaders in the chain of err,
+// the keys are canonicalized. The outer values win if the same header is attached more than once.
+func GetHTTPHeaders(err error) http.Header {
+	var header http.Header
+	var chain []http.Header
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*httpHeadersError); ok {
+			chain = append(chain, e.header)
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i] {
+			if header == nil {
+				header = http.Header{}
+			}
+			header[textproto.CanonicalMIMEHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+	return header
+}
+
+// AsHTTPProblem converts err into the RFC 7807 ProblemDetails, and returns it with the http status.
+// The type is the kebab-case of the reason, or "about:blank" if the reason is not set.
+// The errors which are not CodeError are treated as CCInternalServer.
+// It returns the zero ProblemDetails with http.StatusOK if err is nil.
+func AsHTTPProblem(err error) (ProblemDetails, int) {
+	if err == nil {
+		return ProblemDetails{}, http.StatusOK
+	}
+
+	e, ok := AsCodeError(err)
+	if !ok {
+		e = &codeError{ErrCode: newUnregisteredErrCode(CCInternalServer, PlatformDefault, 0, "ErrInternalServer")}
+	}
+
+	problemType := "about:blank"
+	if reason := e.GetErrCode().GetReason(); reason != "" {
+		problemType = strings.ToLower(strings.ReplaceAll(reason, "_", "-"))
+	}
+
+	httpStatus := e.GetHTTPStatus()
+	return ProblemDetails{
+		Type:   problemType,
+		Title:  e.GetMessage(),
+		Status: httpStatus,
+		Detail: e.GetDetails(),
+		Code:   e.GetCode(),
+	}, httpStatus
+}
+
+// WrapHTTPClientError converts a non-2xx *http.Response into a CodeError.
+// If the body is the standard envelope with code and message, and the http status of the code agrees with
+// the http status code, the CodeError is reconstructed from it,
+// otherwise the category code is derived from the http status code and the body is used as the cause.
+// It reads at most 1MiB of the body and closes it, and returns nil if resp is nil or 2xx.
+func WrapHTTPClientError(resp *http.Response) error {
+	if resp == nil || (resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices) {
+		return nil
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		defer resp.Body.Close()
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxHTTPClientErrorBodySize))
+	}
+
+	var envelope httpErrorEnvelope
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Code != 0 {
+		var opts []ErrCodeOption
+		if IsValidReason(envelope.Reason) {
+			opts = append(opts, WithReason(envelope.Reason))
+		}
+		c := RestoreErrCode(envelope.Code, envelope.Message, opts...)
+		if isErrorCategory(c.GetCategoryCode()) && c.GetHTTPStatus() == resp.StatusCode {
+			if envelope.Details != "" {
+				return newCodeError(c, nil, 0, "%s", envelope.Details)
+			}
+			return newCodeError(c, nil, 0)
+		}
+	}
+
+	c := newUnregisteredErrCode(resp.StatusCode, 0, 0, http.StatusText(resp.StatusCode))
+	if len(body) > 0 {
+		// The cause has no stack, so that the stack starts at the caller.
+		return newCodeError(c, fmt.Errorf("%s", body), 0)
+	}
+	return newCodeError(c, nil, 0)
+}
+
+func (e *httpHeadersError) Cause() error { return e.error }
+
+func (e *httpHeadersError) Unwrap() error { return e.error }
+
+func (e *httpHeadersError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = fmt.Fprintf(s, "%+v", e.Cause())
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
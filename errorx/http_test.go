@@ -0,0 +1,204 @@
+package errorx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapHTTPClientError(t *testing.T) {
+	tests := []struct {
+		name            string
+		resp            *http.Response
+		expectedCode    int
+		expectedMessage string
+		expectedReason  string
+		expectedDetails string
+		expectedCause   string
+	}{{
+		name: "nil",
+		resp: nil,
+	}, {
+		name: "ok",
+		resp: newTestHTTPResponse(http.StatusOK, `{"code":0,"message":"Success"}`),
+	}, {
+		name:            "envelope",
+		resp:            newTestHTTPResponse(http.StatusNotFound, `{"code":40401000,"message":"ErrNotFound","reason":"RESOURCE_NOT_FOUND"}`),
+		expectedCode:    40401000,
+		expectedMessage: "ErrNotFound",
+		expectedReason:  "RESOURCE_NOT_FOUND",
+	}, {
+		name:            "envelope:details",
+		resp:            newTestHTTPResponse(http.StatusBadRequest, `{"code":40001001,"message":"ErrParam","details":"id 100%"}`),
+		expectedCode:    40001001,
+		expectedMessage: "ErrParam",
+		expectedDetails: "id 100%",
+	}, {
+		name:            "envelope:invalid:reason",
+		resp:            newTestHTTPResponse(http.StatusNotFound, `{"code":40401000,"message":"ErrNotFound","reason":"not found"}`),
+		expectedCode:    40401000,
+		expectedMessage: "ErrNotFound",
+	}, {
+		name:            "envelope:foreign:code",
+		resp:            newTestHTTPResponse(http.StatusBadGateway, `{"code":1234,"message":"upstream failed"}`),
+		expectedCode:    50200000,
+		expectedMessage: "Bad Gateway",
+		expectedCause:   `{"code":1234,"message":"upstream failed"}`,
+	}, {
+		name:            "envelope:status:mismatch",
+		resp:            newTestHTTPResponse(http.StatusBadGateway, `{"code":40401000,"message":"ErrNotFound"}`),
+		expectedCode:    50200000,
+		expectedMessage: "Bad Gateway",
+		expectedCause:   `{"code":40401000,"message":"ErrNotFound"}`,
+	}, {
+		name:            "plain",
+		resp:            newTestHTTPResponse(http.StatusInternalServerError, "upstream failed"),
+		expectedCode:    50000000,
+		expectedMessage: "Internal Server Error",
+		expectedCause:   "upstream failed",
+	}, {
+		name:            "plain:json:no:code",
+		resp:            newTestHTTPResponse(http.StatusBadGateway, `{"error":"bad gateway"}`),
+		expectedCode:    50200000,
+		expectedMessage: "Bad Gateway",
+		expectedCause:   `{"error":"bad gateway"}`,
+	}, {
+		name:            "plain:empty",
+		resp:            newTestHTTPResponse(http.StatusInternalServerError, ""),
+		expectedCode:    50000000,
+		expectedMessage: "Internal Server Error",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := WrapHTTPClientError(test.resp)
+			if test.expectedCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			e, ok := AsCodeError(err)
+			if assert.True(t, ok) {
+				assert.Equal(t, test.expectedCode, e.GetCode())
+				assert.Equal(t, test.expectedMessage, e.GetMessage())
+				assert.Equal(t, test.expectedReason, e.GetErrCode().GetReason())
+				assert.Equal(t, test.expectedDetails, e.GetDetails())
+				if test.expectedCause == "" {
+					assert.NoError(t, errors.Unwrap(err))
+				} else {
+					assert.EqualError(t, errors.Unwrap(err), test.expectedCause)
+				}
+				assert.Contains(t, fmt.Sprintf("%+v", err), "errorx.TestWrapHTTPClientError")
+				assert.NotContains(t, fmt.Sprintf("%+v", err), "errorx.WrapHTTPClientError")
+			}
+		})
+	}
+}
+
+func TestWrapHTTPClientErrorLargeBody(t *testing.T) {
+	body := strings.Repeat("x", maxHTTPClientErrorBodySize+10)
+	err := WrapHTTPClientError(newTestHTTPResponse(http.StatusBadGateway, body))
+	if assert.Error(t, errors.Unwrap(err)) {
+		assert.Len(t, errors.Unwrap(err).Error(), maxHTTPClientErrorBodySize)
+	}
+}
+
+func TestWrapHTTPClientErrorServer(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":40401000,"message":"testErrNotFound"}`))
+	}))
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL) //nolint:noctx
+	if assert.NoError(t, err) {
+		err = WrapHTTPClientError(resp)
+		assert.True(t, IsCodeError(err))
+		assert.Equal(t, "40401000(testErrNotFound)", err.Error())
+	}
+}
+
+func TestWithHTTPHeaders(t *testing.T) {
+	assert.NoError(t, WithHTTPHeaders(nil, http.Header{"Retry-After": {"10"}}))
+	assert.Nil(t, GetHTTPHeaders(nil))
+	assert.Nil(t, GetHTTPHeaders(WithCode(testErrUnauthorized, nil)))
+
+	err := WithHTTPHeaders(WithCode(testErrUnauthorized, nil), http.Header{
+		"Www-Authenticate": {`Bearer realm="test"`},
+		"Retry-After":      {"10"},
+	})
+	err = errors.WithMessage(err, "annotation")
+	err = WithHTTPHeaders(err, http.Header{"Retry-After": {"20"}})
+
+	assert.Equal(t, "annotation: 40101000(testErrUnauthorized)", err.Error())
+	assert.Equal(t, `"annotation: 40101000(testErrUnauthorized)"`, fmt.Sprintf("%q", err))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "errorx.TestWithHTTPHeaders")
+	assert.True(t, IsCodeError(err, testErrUnauthorized))
+
+	header := GetHTTPHeaders(err)
+	assert.Equal(t, `Bearer realm="test"`, header.Get("WWW-Authenticate"))
+	assert.Equal(t, "20", header.Get("Retry-After"))
+
+	attached := http.Header{"WWW-Authenticate": {`Bearer realm="outer"`}}
+	err = WithHTTPHeaders(err, attached)
+	header = GetHTTPHeaders(err)
+	assert.Equal(t, http.Header{
+		"Www-Authenticate": {`Bearer realm="outer"`},
+		"Retry-After":      {"20"},
+	}, header)
+	header["Www-Authenticate"][0] = "changed"
+	assert.Equal(t, `Bearer realm="outer"`, attached["WWW-Authenticate"][0])
+}
+
+func TestAsHTTPProblem(t *testing.T) {
+	problem, httpStatus := AsHTTPProblem(nil)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, ProblemDetails{}, problem)
+
+	c := NewErrCode(CCNotFound, testCPCloudServer, 2, "ErrUserNotFound", WithReason("USER_NOT_FOUND"))
+	problem, httpStatus = AsHTTPProblem(errors.WithMessage(WithCode(c, nil, "user %d", 10), "annotation"))
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+	assert.Equal(t, ProblemDetails{
+		Type:   "user-not-found",
+		Title:  "ErrUserNotFound",
+		Status: http.StatusNotFound,
+		Detail: "user 10",
+		Code:   40401002,
+	}, problem)
+
+	problem, httpStatus = AsHTTPProblem(WithCode(testErrNotFound, nil))
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+	assert.Equal(t, ProblemDetails{
+		Type:   "about:blank",
+		Title:  "testErrNotFound",
+		Status: http.StatusNotFound,
+		Code:   40401000,
+	}, problem)
+
+	problem, httpStatus = AsHTTPProblem(errors.New("otherError"))
+	assert.Equal(t, http.StatusInternalServerError, httpStatus)
+	assert.Equal(t, ProblemDetails{
+		Type:   "about:blank",
+		Title:  "ErrInternalServer",
+		Status: http.StatusInternalServerError,
+		Code:   50000000,
+	}, problem)
+
+	SetDefaultPlatform(45)
+	defer SetDefaultPlatform(0)
+	problem, _ = AsHTTPProblem(errors.New("otherError"))
+	assert.Equal(t, 50045000, problem.Code)
+}
+
+func newTestHTTPResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
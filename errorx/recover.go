@@ -0,0 +1,58 @@
+package errorx
+
+import (
+	"github.com/pkg/errors"
+)
+
+type (
+	// RecoverOption is the optional setting for RecoverInto.
+	RecoverOption func(*recoverOptions)
+
+	recoverOptions struct {
+		code *ErrCode
+	}
+)
+
+// WithRecoverCode sets the code of the error converted from the panic, the default is CCInternalServer.
+func WithRecoverCode(c *ErrCode) RecoverOption {
+	return func(o *recoverOptions) {
+		o.code = c
+	}
+}
+
+// RecoverInto recovers the panic and stores it into errp as a CodeError, it must be called directly by defer.
+// The leading args can be RecoverOption, and the rest are details with format string including args as WithCode.
+// For example:
+//
+//	defer errorx.RecoverInto(&err)
+//	defer errorx.RecoverInto(&err, errorx.WithRecoverCode(ErrInternalServer), "while processing %s", id)
+func RecoverInto(errp *error, optsWithFormatArgs ...interface{}) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	o := recoverOptions{}
+	i := 0
+	for ; i < len(optsWithFormatArgs); i++ {
+		opt, ok := optsWithFormatArgs[i].(RecoverOption)
+		if !ok {
+			break
+		}
+		opt(&o)
+	}
+	if o.code == nil {
+		o.code = newUnregisteredErrCode(CCInternalServer, PlatformDefault, 0, "ErrInternalServer")
+	}
+
+	cause, ok := r.(error)
+	if ok {
+		cause = errors.WithMessage(cause, "panic")
+	} else {
+		cause = errors.Errorf("panic: %v", r)
+	}
+
+	if errp != nil {
+		*errp = WithCode(o.code, cause, optsWithFormatArgs[i:]...)
+	}
+}
@@ -0,0 +1,106 @@
+package errorx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverInto(t *testing.T) {
+	tests := []struct {
+		name            string
+		fn              func() error
+		expectedCode    int
+		expectedDetails string
+		expectedCause   string
+	}{{
+		name: "no panic",
+		fn: func() (err error) {
+			defer RecoverInto(&err)
+			return nil
+		},
+	}, {
+		name: "no panic keep error",
+		fn: func() (err error) {
+			defer RecoverInto(&err)
+			return WithCode(testErrNotFound, nil)
+		},
+		expectedCode: 40401000,
+	}, {
+		name: "default",
+		fn: func() (err error) {
+			defer RecoverInto(&err)
+			panic("boom")
+		},
+		expectedCode:  50000000,
+		expectedCause: "panic: boom",
+	}, {
+		name: "panic error",
+		fn: func() (err error) {
+			defer RecoverInto(&err)
+			panic(errors.New("boom"))
+		},
+		expectedCode:  50000000,
+		expectedCause: "panic: boom",
+	}, {
+		name: "code",
+		fn: func() (err error) {
+			defer RecoverInto(&err, WithRecoverCode(testErrInternalServer))
+			panic("boom")
+		},
+		expectedCode:  50001000,
+		expectedCause: "panic: boom",
+	}, {
+		name: "code and message",
+		fn: func() (err error) {
+			defer RecoverInto(&err, WithRecoverCode(testErrInternalServer), "while processing %s", "id")
+			panic("boom")
+		},
+		expectedCode:    50001000,
+		expectedDetails: "while processing id",
+		expectedCause:   "panic: boom",
+	}, {
+		name: "message",
+		fn: func() (err error) {
+			defer RecoverInto(&err, "while processing")
+			panic("boom")
+		},
+		expectedCode:    50000000,
+		expectedDetails: "while processing",
+		expectedCause:   "panic: boom",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.fn()
+			if test.expectedCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			e, ok := AsCodeError(err)
+			if assert.True(t, ok) {
+				assert.Equal(t, test.expectedCode, e.GetCode())
+				assert.Equal(t, test.expectedDetails, e.GetDetails())
+				if test.expectedCause != "" {
+					assert.EqualError(t, errors.Unwrap(err), test.expectedCause)
+					assert.Contains(t, fmt.Sprintf("%+v", err), "errorx.TestRecoverInto")
+				}
+			}
+		})
+	}
+
+	assert.NotPanics(t, func() {
+		defer RecoverInto(nil)
+		panic("boom")
+	})
+
+	SetDefaultPlatform(45)
+	defer SetDefaultPlatform(0)
+	err := func() (err error) {
+		defer RecoverInto(&err)
+		panic("boom")
+	}()
+	assert.Equal(t, 50045000, err.(CodeError).GetCode())
+}
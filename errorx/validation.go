@@ -0,0 +1,106 @@
+package errorx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var _ ValidationError = (*validationError)(nil)
+
+type (
+	// FieldViolation describes a field which failed the validation.
+	FieldViolation struct {
+		Field string `json:"field"`
+		Tag   string `json:"tag"`
+		Param string `json:"param,omitempty"`
+	}
+
+	ValidationError interface {
+		error
+		GetFieldViolations() []FieldViolation
+	}
+
+	validationError struct {
+		error
+		violations []FieldViolation
+	}
+
+	// validationFieldError is the duck-typing of validator.FieldError.
+	validationFieldError interface {
+		Field() string
+		Tag() string
+		Param() string
+	}
+)
+
+// FromValidationErrors converts the validator.ValidationErrors into a CCBadRequest CodeError,
+// whose details list each failing field and rule, and the field violations can be got by AsValidationError.
+// It accepts any slice error whose elements have the methods Field, Tag and Param, so it doesn't depend on the validator library.
+// The validation error can be wrapped, such as by fmt.Errorf with %w, it's found by walking the Unwrap chain.
+// It returns err directly if err is not a validation error.
+func FromValidationErrors(err error) error {
+	violations, ok := getFieldViolations(err)
+	if !ok {
+		return err
+	}
+
+	strs := make([]string, len(violations))
+	for i, v := range violations {
+		rule := v.Tag
+		if v.Param != "" {
+			rule += "=" + v.Param
+		}
+		strs[i] = fmt.Sprintf("%s failed on %s", v.Field, rule)
+	}
+
+	c := newUnregisteredErrCode(CCBadRequest, PlatformDefault, 0, "ErrBadRequest")
+	return newCodeError(c, &validationError{error: err, violations: violations}, 0, "%s", strings.Join(strs, "; "))
+}
+
+func AsValidationError(err error) (ValidationError, bool) {
+	if e := new(validationError); errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+func (e *validationError) GetFieldViolations() []FieldViolation {
+	return e.violations
+}
+
+func (e *validationError) Cause() error { return e.error }
+
+func (e *validationError) Unwrap() error { return e.error }
+
+func getFieldViolations(err error) ([]FieldViolation, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if violations, ok := getSliceFieldViolations(err); ok {
+			return violations, true
+		}
+	}
+	return nil, false
+}
+
+func getSliceFieldViolations(err error) ([]FieldViolation, bool) {
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return nil, false
+	}
+
+	violations := make([]FieldViolation, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		fe, ok := v.Index(i).Interface().(validationFieldError)
+		if !ok {
+			return nil, false
+		}
+		violations[i] = FieldViolation{
+			Field: fe.Field(),
+			Tag:   fe.Tag(),
+			Param: fe.Param(),
+		}
+	}
+	return violations, true
+}
@@ -0,0 +1,90 @@
+package errorx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testFieldError struct {
+		field, tag, param string
+	}
+
+	testValidationErrors []testFieldError
+)
+
+func TestFromValidationErrors(t *testing.T) {
+	assert.NoError(t, FromValidationErrors(nil))
+
+	otherErr := errors.New("otherError")
+	assert.Equal(t, otherErr, FromValidationErrors(otherErr))
+	assert.Equal(t, testValidationErrors{}, FromValidationErrors(testValidationErrors{}))
+
+	validationErrs := testValidationErrors{
+		{field: "Name", tag: "required"},
+		{field: "Age", tag: "gte", param: "18"},
+	}
+	err := FromValidationErrors(validationErrs)
+
+	e, ok := AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CCBadRequest, e.GetCategoryCode())
+		assert.Equal(t, "Name failed on required; Age failed on gte=18", e.GetDetails())
+	}
+	assert.Contains(t, fmt.Sprintf("%+v", err), "\ngithub.com/vesoft-inc/go-pkg/errorx.TestFromValidationErrors\n")
+	assert.NotContains(t, fmt.Sprintf("%+v", err), "errorx.FromValidationErrors")
+
+	ve, ok := AsValidationError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, []FieldViolation{
+			{Field: "Name", Tag: "required"},
+			{Field: "Age", Tag: "gte", Param: "18"},
+		}, ve.GetFieldViolations())
+	}
+
+	var target testValidationErrors
+	assert.True(t, errors.As(err, &target))
+	assert.Equal(t, validationErrs, target)
+
+	_, ok = AsValidationError(otherErr)
+	assert.False(t, ok)
+
+	validationErrs = testValidationErrors{
+		{field: "Name", tag: "contains", param: "%"},
+		{field: "Rate", tag: "startswith", param: "50%d"},
+	}
+	err = FromValidationErrors(fmt.Errorf("bind request: %w", validationErrs))
+	e, ok = AsCodeError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, CCBadRequest, e.GetCategoryCode())
+		assert.Equal(t, "Name failed on contains=%; Rate failed on startswith=50%d", e.GetDetails())
+	}
+	ve, ok = AsValidationError(err)
+	if assert.True(t, ok) {
+		assert.Len(t, ve.GetFieldViolations(), 2)
+	}
+
+	SetDefaultPlatform(45)
+	defer SetDefaultPlatform(0)
+	assert.Equal(t, 40045000, FromValidationErrors(validationErrs).(CodeError).GetCode())
+	assert.True(t, errors.As(err, &target))
+	assert.Equal(t, validationErrs, target)
+}
+
+func (e testFieldError) Field() string { return e.field }
+
+func (e testFieldError) Tag() string { return e.tag }
+
+func (e testFieldError) Param() string { return e.param }
+
+func (e testValidationErrors) Error() string {
+	strs := make([]string, len(e))
+	for i, fe := range e {
+		strs[i] = fmt.Sprintf("%s:%s", fe.field, fe.tag)
+	}
+	return strings.Join(strs, ",")
+}
@@ -26,6 +26,7 @@ const (
 	standardHandlerFieldMessage = "message"
 	standardHandlerFieldData    = "data"
 	standardHandlerFieldDetails = "details"
+	standardHandlerFieldReason  = "reason"
 )
 
 var _ Handler = (*standardHandler)(nil)
@@ -126,7 +127,7 @@ func (h *standardHandler) GetStatusBody(r *http.Request, data interface{}, err e
 				}
 				return h.params.GetErrCode(err)
 			}, func() *errorx.ErrCode {
-				return errorx.NewErrCode(errorx.CCInternalServer, 0, 0, "ErrInternalServer")
+				return errorx.NewUnregisteredErrCode(errorx.CCInternalServer, 0, 0, "ErrInternalServer")
 			}), err)
 			e, _ = errorx.AsCodeError(err)
 		}
@@ -148,6 +149,9 @@ func (h *standardHandler) GetStatusBody(r *http.Request, data interface{}, err e
 				standardHandlerFieldCode:    e.GetCode(),
 				standardHandlerFieldMessage: e.GetMessage(),
 			}
+			if reason := e.GetErrCode().GetReason(); reason != "" {
+				resp[standardHandlerFieldReason] = reason
+			}
 			if details := h.getDetails(e); details != "" {
 				resp[standardHandlerFieldDetails] = details
 			}
@@ -170,6 +174,12 @@ func (h *standardHandler) GetStatusBody(r *http.Request, data interface{}, err e
 
 func (h *standardHandler) Handle(w http.ResponseWriter, r *http.Request, data interface{}, err error) {
 	httpStatus, body := h.GetStatusBody(r, data, err)
+	for k, vs := range errorx.GetHTTPHeaders(err) {
+		w.Header().Del(k)
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
 	if body == nil {
 		w.WriteHeader(httpStatus)
 		return
@@ -242,7 +252,7 @@ func (h *standardHandler) getDetails(e errorx.CodeError) string {
 		}
 		return e.Error()
 	case StandardHandlerDetailsFull:
-		return fmt.Sprintf("%+v", e)
+		return errorx.DebugString(e)
 	}
 	return ""
 }
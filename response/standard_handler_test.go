@@ -93,6 +93,17 @@ func TestStandardHandler(t *testing.T) {
 			"code":    40301002,
 			"message": "testError",
 		},
+	}, {
+		name:           "data:no:error:errorx:reason",
+		params:         StandardHandlerParams{},
+		r:              httptest.NewRequest("GET", "http://localhost", nil),
+		err:            errorx.WithCode(errorx.NewErrCode(404, 1, 2, "testError", errorx.WithReason("RESOURCE_NOT_FOUND")), nil),
+		expectedStatus: 404,
+		expectedBody: map[string]interface{}{
+			"code":    40401002,
+			"message": "testError",
+			"reason":  "RESOURCE_NOT_FOUND",
+		},
 	}, {
 		name: "data:no:error:GetErrCode",
 		params: StandardHandlerParams{
@@ -406,6 +417,7 @@ func TestStandardHandler(t *testing.T) {
 						Message string
 						Data    interface{}
 						Details string
+						Reason  string
 					}{}
 					err := json.Unmarshal(bodyBytes, &bodyStruct)
 					assert.NoError(t, err)
@@ -419,6 +431,9 @@ func TestStandardHandler(t *testing.T) {
 					if bodyStruct.Details != "" {
 						cmpBody["details"] = bodyStruct.Details
 					}
+					if bodyStruct.Reason != "" {
+						cmpBody["reason"] = bodyStruct.Reason
+					}
 					body = cmpBody
 				}
 				checkFunc(expectedStatus, httpStatus, expectedBody, body)
@@ -490,3 +505,38 @@ func TestStandardHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestStandardHandlerHTTPHeaders(t *testing.T) {
+	err := errorx.WithHTTPHeaders(
+		errorx.WithCode(errorx.NewErrCode(401, 1, 2, "testError"), nil),
+		http.Header{"Www-Authenticate": {`Bearer realm="test"`}},
+	)
+
+	h := NewStandardHandler(StandardHandlerParams{})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "http://localhost", nil), nil, err)
+	assert.Equal(t, 401, rec.Code)
+	assert.Equal(t, `Bearer realm="test"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	h.Handle(rec, nil, nil, err)
+	assert.Equal(t, 401, rec.Code)
+	assert.Equal(t, `Bearer realm="test"`, rec.Header().Get("WWW-Authenticate"))
+
+	err = errorx.WithHTTPHeaders(err, http.Header{"WWW-Authenticate": {`Bearer realm="outer"`}})
+	rec = httptest.NewRecorder()
+	rec.Header().Set("WWW-Authenticate", `Bearer realm="preset"`)
+	h.Handle(rec, nil, nil, err)
+	assert.Equal(t, []string{`Bearer realm="outer"`}, rec.Header().Values("WWW-Authenticate"))
+}
+
+func TestStandardHandlerUnmappedErrorNotRegistered(t *testing.T) {
+	codes := errorx.All()
+
+	h := NewStandardHandler(StandardHandlerParams{})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, nil, nil, errors.New("unmappedError"))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, codes, errorx.All())
+}